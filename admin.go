@@ -0,0 +1,168 @@
+package fir
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/livefir/fir/pubsub"
+)
+
+// adminEventLogSize is the number of recently dispatched pubsub events retained per
+// channel for the admin introspection endpoint.
+const adminEventLogSize = 20
+
+// adminEventLog keeps a bounded tail of pubsub.Events dispatched per channel.
+type adminEventLog struct {
+	mu        sync.RWMutex
+	byChannel map[string][]pubsub.Event
+}
+
+func newAdminEventLog() *adminEventLog {
+	return &adminEventLog{byChannel: make(map[string][]pubsub.Event)}
+}
+
+func (l *adminEventLog) record(channel string, event pubsub.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := append(l.byChannel[channel], event)
+	if len(events) > adminEventLogSize {
+		events = events[len(events)-adminEventLogSize:]
+	}
+	l.byChannel[channel] = events
+}
+
+func (l *adminEventLog) snapshot() map[string][]pubsub.Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make(map[string][]pubsub.Event, len(l.byChannel))
+	for channel, events := range l.byChannel {
+		out[channel] = append([]pubsub.Event(nil), events...)
+	}
+	return out
+}
+
+// adminPubsubAdapter wraps a pubsub.Adapter to record a tail of recently published
+// events per channel, leaving every other adapter method untouched.
+type adminPubsubAdapter struct {
+	pubsub.Adapter
+	log *adminEventLog
+}
+
+func (a *adminPubsubAdapter) Publish(ctx context.Context, channel string, event pubsub.Event) error {
+	a.log.record(channel, event)
+	return a.Adapter.Publish(ctx, channel, event)
+}
+
+// adminUnwrapPubsub returns the pubsub.Adapter the controller was configured
+// with, unwrapping adminPubsubAdapter so the snapshot reports the adapter the
+// caller actually set via WithPubsubAdapter instead of always reporting the
+// recording wrapper admin enables it with.
+func adminUnwrapPubsub(adapter pubsub.Adapter) pubsub.Adapter {
+	if a, ok := adapter.(*adminPubsubAdapter); ok {
+		return a.Adapter
+	}
+	return adapter
+}
+
+type adminRoute struct {
+	ID         string              `json:"id"`
+	Events     map[string][]string `json:"events"`
+	CacheItems int                 `json:"cache_items"`
+}
+
+type adminOptions struct {
+	ChannelFunc          string   `json:"channel_func"`
+	SessionName          string   `json:"session_name"`
+	DevelopmentMode      bool     `json:"development_mode"`
+	DebugLog             bool     `json:"debug_log"`
+	DisableWebsocket     bool     `json:"disable_websocket"`
+	DisableTemplateCache bool     `json:"disable_template_cache"`
+	WatchExts            []string `json:"watch_exts"`
+}
+
+type adminSnapshot struct {
+	AppName       string                    `json:"app_name"`
+	PubsubAdapter string                    `json:"pubsub_adapter"`
+	Options       adminOptions              `json:"options"`
+	Routes        []adminRoute              `json:"routes"`
+	RecentEvents  map[string][]pubsub.Event `json:"recent_events,omitempty"`
+}
+
+func funcName(f any) string {
+	if f == nil {
+		return ""
+	}
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// Admin returns an http.HandlerFunc serving the admin introspection endpoint
+// registered via WithAdminEndpoint. It is a no-op 404 if the option wasn't set.
+func (c *controller) Admin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c.adminPath == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/cache/invalidate") {
+			c.adminInvalidateCache(w, r)
+			return
+		}
+		c.adminServeSnapshot(w, r)
+	}
+}
+
+func (c *controller) adminServeSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot := adminSnapshot{
+		AppName:       c.name,
+		PubsubAdapter: reflect.TypeOf(adminUnwrapPubsub(c.pubsub)).String(),
+		Options: adminOptions{
+			ChannelFunc:          funcName(c.channelFunc),
+			SessionName:          c.sessionName,
+			DevelopmentMode:      c.developmentMode,
+			DebugLog:             c.debugLog,
+			DisableWebsocket:     c.disableWebsocket,
+			DisableTemplateCache: c.disableTemplateCache,
+			WatchExts:            c.watchExts,
+		},
+	}
+
+	for id, rt := range c.routes {
+		snapshot.Routes = append(snapshot.Routes, adminRoute{
+			ID:         id,
+			Events:     rt.bindings.EventTemplates(),
+			CacheItems: rt.cache.ItemCount(),
+		})
+	}
+
+	if c.adminEventLog != nil {
+		snapshot.RecentEvents = c.adminEventLog.snapshot()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// adminInvalidateCache forces every route to re-parse its templates on next render
+// by dropping the cached *template.Template. It refuses to do so when template
+// caching is already disabled since there is nothing cached to invalidate. The
+// assignment is guarded by controller.templateMu, the same lock renders take to
+// read a route's template, since route goroutines read it concurrently.
+func (c *controller) adminInvalidateCache(w http.ResponseWriter, r *http.Request) {
+	if c.disableTemplateCache {
+		http.Error(w, "template cache is already disabled", http.StatusBadRequest)
+		return
+	}
+	c.templateMu.Lock()
+	for _, rt := range c.routes {
+		rt.template = nil
+	}
+	c.templateMu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
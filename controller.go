@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/schema"
@@ -15,6 +17,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/lithammer/shortuuid/v4"
 	"github.com/livefir/fir/pubsub"
+	"github.com/livefir/fir/transport"
 )
 
 // Controller is an interface which encapsulates a group of views. It routes requests to the appropriate view.
@@ -22,6 +25,20 @@ import (
 type Controller interface {
 	Route(route Route) http.HandlerFunc
 	RouteFunc(options RouteFunc) http.HandlerFunc
+	// Admin returns an http.HandlerFunc serving the admin introspection endpoint
+	// registered via WithAdminEndpoint. It is a no-op 404 if the option wasn't set.
+	Admin() http.HandlerFunc
+	// SSE returns an http.HandlerFunc serving the server-sent events endpoint
+	// registered via WithSSETransport. It is a no-op 404 if the option wasn't set.
+	SSE() http.HandlerFunc
+	// WS returns an http.HandlerFunc serving the websocket endpoint for the route
+	// registered under routeID, upgrading through the configured Transport and
+	// pumping the route's pubsub channel to it. It is a no-op 404 if websockets
+	// are disabled or routeID is unknown.
+	WS(routeID string) http.HandlerFunc
+	// CloseSession proactively drops sessionID's transport connection, which in
+	// turn unwinds its pubsub subscription.
+	CloseSession(sessionID string) error
 }
 
 type opt struct {
@@ -29,8 +46,14 @@ type opt struct {
 	pathParamsFunc    func(r *http.Request) PathParams
 	websocketUpgrader websocket.Upgrader
 
+	websocketReadDeadline  time.Duration
+	websocketWriteDeadline time.Duration
+	websocketPingInterval  time.Duration
+	websocketPongWait      time.Duration
+
 	disableTemplateCache bool
 	disableWebsocket     bool
+	enableSSE            bool
 	debugLog             bool
 	enableWatch          bool
 	watchExts            []string
@@ -39,11 +62,14 @@ type opt struct {
 	embedFS              embed.FS
 	hasEmbedFS           bool
 	pubsub               pubsub.Adapter
+	transport            Transport
 	appName              string
 	formDecoder          *schema.Decoder
 	sessionStore         sessions.Store
 	sessionKeyPairs      [][]byte
 	sessionName          string
+	adminPath            string
+	eventFlushInterval   time.Duration
 }
 
 // ControllerOption is an option for the controller.
@@ -91,6 +117,51 @@ func WithWebsocketUpgrader(upgrader websocket.Upgrader) ControllerOption {
 	}
 }
 
+// WithTransport is an option to set the transport used to deliver dom.Event
+// payloads to clients, alongside WithPubsubAdapter which selects how events are
+// distributed between controller instances. Defaults to transport.Websocket built
+// from the configured websocket upgrader.
+func WithTransport(t Transport) ControllerOption {
+	return func(o *opt) {
+		o.transport = t
+	}
+}
+
+// WithWebsocketReadDeadline is an option to set the read deadline reapplied
+// before every websocket read. Without one, an idle load balancer timeout
+// (typically 60s) silently severs the connection and the client never finds out.
+func WithWebsocketReadDeadline(d time.Duration) ControllerOption {
+	return func(o *opt) {
+		o.websocketReadDeadline = d
+	}
+}
+
+// WithWebsocketWriteDeadline is an option to set the write deadline reapplied
+// before every websocket write, including pings.
+func WithWebsocketWriteDeadline(d time.Duration) ControllerOption {
+	return func(o *opt) {
+		o.websocketWriteDeadline = d
+	}
+}
+
+// WithWebsocketPingInterval is an option to set how often the controller pings
+// idle websocket connections to keep them alive through intermediaries that
+// close connections after a period of inactivity.
+func WithWebsocketPingInterval(d time.Duration) ControllerOption {
+	return func(o *opt) {
+		o.websocketPingInterval = d
+	}
+}
+
+// WithWebsocketPongWait is an option to set how long the controller waits for a
+// pong before closing an unresponsive websocket connection. Only effective when
+// WithWebsocketPingInterval is also set.
+func WithWebsocketPongWait(d time.Duration) ControllerOption {
+	return func(o *opt) {
+		o.websocketPongWait = d
+	}
+}
+
 // WithEmbedFS is an option to set the embed.FS for the controller.
 func WithEmbedFS(fs embed.FS) ControllerOption {
 	return func(o *opt) {
@@ -119,6 +190,18 @@ func WithDisableWebsocket() ControllerOption {
 	}
 }
 
+// WithSSETransport is an option to enable a server-sent events endpoint as a
+// websocket alternative for environments where websockets are unavailable
+// (corporate proxies, HTTP/1.1-only environments, mobile background). Mount
+// Controller.SSE() at /fir/sse and connect from the client with
+// static/js/fir-sse.js; the dom.Event stream produced by domEvents is delivered
+// over text/event-stream instead of a websocket frame.
+func WithSSETransport() ControllerOption {
+	return func(o *opt) {
+		o.enableSSE = true
+	}
+}
+
 // DisableTemplateCache is an option to disable template caching. This is useful for development.
 func DisableTemplateCache() ControllerOption {
 	return func(o *opt) {
@@ -151,6 +234,27 @@ func DevelopmentMode(enable bool) ControllerOption {
 	}
 }
 
+// WithAdminEndpoint is an option to enable the admin introspection endpoint at path.
+// The endpoint serves JSON diagnostics about registered routes, event bindings,
+// effective controller options and a tail of recently dispatched pubsub events, and
+// exposes a route to force-invalidate the route template cache. Mount it with
+// Controller.Admin() on your own router.
+func WithAdminEndpoint(path string) ControllerOption {
+	return func(o *opt) {
+		o.adminPath = path
+	}
+}
+
+// WithEventFlushInterval is an option to set the window over which dispatched
+// pubsub.Events are coalesced, keyed on (sessionID, eventType, target), before
+// being rendered and flushed to a session's transport as a single message.
+// Defaults to defaultEventFlushInterval (16ms).
+func WithEventFlushInterval(d time.Duration) ControllerOption {
+	return func(o *opt) {
+		o.eventFlushInterval = d
+	}
+}
+
 // NewController creates a new controller.
 func NewController(name string, options ...ControllerOption) Controller {
 	if name == "" {
@@ -172,14 +276,15 @@ func NewController(name string, options ...ControllerOption) Controller {
 	})
 
 	o := &opt{
-		channelFunc:       defaultChannelFunc,
-		websocketUpgrader: websocket.Upgrader{EnableCompression: true},
-		watchExts:         defaultWatchExtensions,
-		pubsub:            pubsub.NewInmem(),
-		appName:           name,
-		formDecoder:       formDecoder,
-		sessionKeyPairs:   [][]byte{[]byte(securecookie.GenerateRandomKey(32))},
-		sessionName:       "_fir_session_",
+		channelFunc:        defaultChannelFunc,
+		websocketUpgrader:  websocket.Upgrader{EnableCompression: true},
+		watchExts:          defaultWatchExtensions,
+		pubsub:             pubsub.NewInmem(),
+		appName:            name,
+		formDecoder:        formDecoder,
+		sessionKeyPairs:    [][]byte{[]byte(securecookie.GenerateRandomKey(32))},
+		sessionName:        "_fir_session_",
+		eventFlushInterval: defaultEventFlushInterval,
 	}
 
 	for _, option := range options {
@@ -188,6 +293,21 @@ func NewController(name string, options ...ControllerOption) Controller {
 
 	o.sessionStore = sessions.NewCookieStore(o.sessionKeyPairs...)
 
+	if o.transport == nil {
+		o.transport = transport.NewWebsocket(o.websocketUpgrader,
+			transport.WithReadDeadline(o.websocketReadDeadline),
+			transport.WithWriteDeadline(o.websocketWriteDeadline),
+			transport.WithPingInterval(o.websocketPingInterval),
+			transport.WithPongWait(o.websocketPongWait),
+		)
+	}
+
+	var eventLog *adminEventLog
+	if o.adminPath != "" {
+		eventLog = newAdminEventLog()
+		o.pubsub = &adminPubsubAdapter{Adapter: o.pubsub, log: eventLog}
+	}
+
 	if o.publicDir == "" {
 		var publicDir string
 		publicDirUsage := "public directory that contains the html template files."
@@ -198,9 +318,10 @@ func NewController(name string, options ...ControllerOption) Controller {
 	}
 
 	c := &controller{
-		opt:    *o,
-		name:   name,
-		routes: make(map[string]*route),
+		opt:           *o,
+		name:          name,
+		routes:        make(map[string]*route),
+		adminEventLog: eventLog,
 	}
 	if c.developmentMode {
 		log.Println("controller starting in developer mode ...", c.developmentMode)
@@ -222,8 +343,12 @@ func NewController(name string, options ...ControllerOption) Controller {
 }
 
 type controller struct {
-	name   string
-	routes map[string]*route
+	name          string
+	routes        map[string]*route
+	adminEventLog *adminEventLog
+	// templateMu guards each route's template field against concurrent access
+	// between renders and admin's cache invalidation endpoint.
+	templateMu sync.RWMutex
 	opt
 }
 
@@ -260,3 +385,13 @@ func (c *controller) RouteFunc(opts RouteFunc) http.HandlerFunc {
 	c.routes[r.id] = r
 	return r.ServeHTTP
 }
+
+// CloseSession proactively drops sessionID's transport connection, which in
+// turn unwinds its pubsub subscription. sessionID is the session_id query
+// parameter a client connected to Controller.WS with; Close only has a
+// connection to tear down once Upgrade has registered one for it, so calling
+// this before the client connects, or after it has already disconnected, is a
+// harmless no-op.
+func (c *controller) CloseSession(sessionID string) error {
+	return c.transport.Close(sessionID)
+}
@@ -0,0 +1,92 @@
+package fir
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/livefir/fir/pubsub"
+)
+
+// defaultEventFlushInterval is the default window over which dispatched
+// pubsub.Events are coalesced before being rendered and flushed to a session's
+// transport, mirroring how reactive front-ends batch DOM updates per frame.
+const defaultEventFlushInterval = 16 * time.Millisecond
+
+// eventFlushKey identifies the slot a pubsub.Event coalesces into: only the
+// latest event for a given (sessionID, eventType, target) survives a flush
+// window.
+type eventFlushKey struct {
+	sessionID string
+	eventType string
+	target    string
+}
+
+func newEventFlushKey(event pubsub.Event) eventFlushKey {
+	var key eventFlushKey
+	if event.SessionID != nil {
+		key.sessionID = *event.SessionID
+	}
+	if event.ID != nil {
+		key.eventType = fmt.Sprintf("%s:%s", *event.ID, event.State)
+	}
+	if event.Target != nil {
+		key.target = *event.Target
+	}
+	return key
+}
+
+// eventFlusher batches pubsub.Events per session within a configurable flush
+// window so a handler publishing multiple events targeting the same block in
+// quick succession produces a single rendered message instead of one per event.
+type eventFlusher struct {
+	interval time.Duration
+	onFlush  func(events []pubsub.Event)
+
+	mu      sync.Mutex
+	pending map[eventFlushKey]pubsub.Event
+	timer   *time.Timer
+}
+
+// newEventFlusher creates an eventFlusher that calls onFlush with the coalesced
+// batch once interval has elapsed since the first event of the batch arrived. A
+// zero or negative interval falls back to defaultEventFlushInterval.
+func newEventFlusher(interval time.Duration, onFlush func(events []pubsub.Event)) *eventFlusher {
+	if interval <= 0 {
+		interval = defaultEventFlushInterval
+	}
+	return &eventFlusher{
+		interval: interval,
+		onFlush:  onFlush,
+		pending:  make(map[eventFlushKey]pubsub.Event),
+	}
+}
+
+// add queues event, keeping only the latest Detail per (sessionID, eventType,
+// target), and schedules a flush after the configured interval if one isn't
+// already pending.
+func (f *eventFlusher) add(event pubsub.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending[newEventFlushKey(event)] = event
+	if f.timer != nil {
+		return
+	}
+	f.timer = time.AfterFunc(f.interval, f.flush)
+}
+
+func (f *eventFlusher) flush() {
+	f.mu.Lock()
+	events := make([]pubsub.Event, 0, len(f.pending))
+	for _, event := range f.pending {
+		events = append(events, event)
+	}
+	f.pending = make(map[eventFlushKey]pubsub.Event)
+	f.timer = nil
+	f.mu.Unlock()
+
+	if len(events) > 0 {
+		f.onFlush(events)
+	}
+}
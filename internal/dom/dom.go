@@ -139,3 +139,20 @@ func (b *Bindings) TemplateNames(eventIDWithState string) []string {
 	}
 	return templateNames
 }
+
+// EventTemplates returns a copy of the parsed event to template name bindings,
+// keyed by event id with the state suffix e.g. myevent:ok. It is intended for
+// read-only introspection such as admin diagnostics.
+func (b *Bindings) EventTemplates() map[string][]string {
+	b.RLock()
+	defer b.RUnlock()
+	out := make(map[string][]string, len(b.eventTemplates))
+	for eventID, templates := range b.eventTemplates {
+		var names []string
+		for name := range templates {
+			names = append(names, name)
+		}
+		out[eventID] = names
+	}
+	return out
+}
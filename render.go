@@ -18,6 +18,49 @@ import (
 // renderDOMEvents renders the DOM events for the given pubsub event.
 // the associated templates for the event are rendered and the dom events are returned.
 func renderDOMEvents(ctx RouteContext, pubsubEvent pubsub.Event) []dom.Event {
+	return trackErrors(ctx, pubsubEvent, renderDOMEventsOnly(ctx, pubsubEvent))
+}
+
+// renderDOMEventsBatch renders a batch of pubsub.Events coalesced by an
+// eventFlusher within a single flush window and runs one trackErrors pass per
+// session in the batch, instead of one renderDOMEvents call per raw event. A
+// batch can legitimately hold events for more than one session since the flush
+// key is (sessionID, eventType, target), so sessions are grouped and tracked
+// independently: this way an error cleared and immediately re-set within the
+// same window sends no unset event for that session, without reading or
+// writing another session's error cache or unsetting its errors. The re-set
+// error event itself is still sent.
+func renderDOMEventsBatch(ctx RouteContext, pubsubEvents []pubsub.Event) []dom.Event {
+	var sessionOrder []string
+	bySession := make(map[string][]pubsub.Event)
+	for _, pubsubEvent := range pubsubEvents {
+		var sessionID string
+		if pubsubEvent.SessionID != nil {
+			sessionID = *pubsubEvent.SessionID
+		}
+		if _, ok := bySession[sessionID]; !ok {
+			sessionOrder = append(sessionOrder, sessionID)
+		}
+		bySession[sessionID] = append(bySession[sessionID], pubsubEvent)
+	}
+
+	var events []dom.Event
+	for _, sessionID := range sessionOrder {
+		sessionEvents := bySession[sessionID]
+		var rendered []dom.Event
+		for _, pubsubEvent := range sessionEvents {
+			rendered = append(rendered, renderDOMEventsOnly(ctx, pubsubEvent)...)
+		}
+		last := sessionEvents[len(sessionEvents)-1]
+		events = append(events, trackErrors(ctx, last, rendered)...)
+	}
+	return events
+}
+
+// renderDOMEventsOnly renders the templates bound to pubsubEvent without running
+// the trackErrors pass, so callers rendering a coalesced batch of events can run
+// trackErrors once over the combined result instead of once per event.
+func renderDOMEventsOnly(ctx RouteContext, pubsubEvent pubsub.Event) []dom.Event {
 	eventIDWithState := fmt.Sprintf("%s:%s", *pubsubEvent.ID, pubsubEvent.State)
 	templateNames := ctx.route.bindings.TemplateNames(eventIDWithState)
 	var events []dom.Event
@@ -42,7 +85,10 @@ func renderDOMEvents(ctx RouteContext, pubsubEvent pubsub.Event) []dom.Event {
 			}
 			templateData = map[string]any{"fir": newRouteDOMContext(ctx, errs)}
 		}
-		value, err := buildTemplateValue(ctx.route.template, templateName, templateData)
+		ctx.route.controller.templateMu.RLock()
+		tmpl := ctx.route.template
+		ctx.route.controller.templateMu.RUnlock()
+		value, err := buildTemplateValue(tmpl, templateName, templateData)
 		if err != nil {
 			klog.Errorf("Bindings.Events buildTemplateValue error for eventType: %v, err: %v", *eventType, err)
 			continue
@@ -60,7 +106,7 @@ func renderDOMEvents(ctx RouteContext, pubsubEvent pubsub.Event) []dom.Event {
 		})
 	}
 
-	return trackErrors(ctx, pubsubEvent, events)
+	return events
 }
 
 func trackErrors(ctx RouteContext, pubsubEvent pubsub.Event, events []dom.Event) []dom.Event {
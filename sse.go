@@ -0,0 +1,100 @@
+package fir
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/livefir/fir/pubsub"
+)
+
+// SSE returns an http.HandlerFunc serving the server-sent events endpoint
+// registered via WithSSETransport. It is a no-op 404 if the option wasn't set.
+func (c *controller) SSE() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.enableSSE {
+			http.NotFound(w, r)
+			return
+		}
+
+		channel := r.URL.Query().Get("channel")
+		if channel == "" {
+			http.Error(w, "channel query param is required", http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := r.Context()
+		events, err := c.pubsub.Subscribe(ctx, channel)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				c.writeSSEEvent(w, event)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeSSEEvent renders a pubsub.Event via domEvents, with the template of the
+// route named by event.RouteID, and writes it as one text/event-stream frame per
+// resulting dom.Event: the DOM event Type as the SSE event name, the minified
+// template detail as the data payload. Unlike Controller.WS, events are neither
+// coalesced nor passed through trackErrors.
+func (c *controller) writeSSEEvent(w http.ResponseWriter, event pubsub.Event) {
+	var tmpl *template.Template
+	if event.RouteID != nil {
+		if rt, ok := c.routes[*event.RouteID]; ok {
+			c.templateMu.RLock()
+			tmpl = rt.template
+			c.templateMu.RUnlock()
+		}
+	}
+
+	data := domEvents(tmpl, []pubsub.Event{event})
+	if data == nil {
+		return
+	}
+
+	var domEvts []DOMEvent
+	if err := json.Unmarshal(data, &domEvts); err != nil {
+		glog.Errorf("sse: error unmarshaling dom events: %v \n", err)
+		return
+	}
+
+	for _, de := range domEvts {
+		var eventType string
+		if de.Type != nil {
+			eventType = *de.Type
+		}
+		detail, err := json.Marshal(de.Detail)
+		if err != nil {
+			glog.Errorf("sse: error marshaling dom event detail: %v \n", err)
+			continue
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, detail)
+	}
+}
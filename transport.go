@@ -0,0 +1,20 @@
+package fir
+
+import "context"
+
+// Transport delivers dom.Event payloads to, and receives raw client messages
+// from, a connected session, decoupling delivery from rendering. renderDOMEvents
+// and domEvents are transport-agnostic; the per-session pump loop reads
+// pubsub.Events, renders them, and calls Send on the configured Transport. This
+// unlocks websockets, SSE, HTTP long-polling, in-memory transports for tests, and
+// gRPC streaming for embedded scenarios behind the same interface.
+type Transport interface {
+	// Subscribe returns a channel of raw payloads received from the client
+	// identified by sessionID. The channel is closed when ctx is canceled or the
+	// underlying connection drops.
+	Subscribe(ctx context.Context, sessionID string) (<-chan []byte, error)
+	// Send delivers payload to the client identified by sessionID.
+	Send(ctx context.Context, sessionID string, payload []byte) error
+	// Close terminates the transport's connection for sessionID, if any.
+	Close(sessionID string) error
+}
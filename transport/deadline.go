@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"sync"
+	"time"
+)
+
+// deadlineTimer maintains a channel that's closed once a deadline fires, so a
+// connection goroutine can select on it alongside reads/writes instead of
+// polling. Resetting the deadline replaces the channel with a fresh one.
+// Borrowed from the deadlineTimer used by gvisor's netstack gonet package.
+type deadlineTimer struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+func newDeadlineTimer() *deadlineTimer {
+	return &deadlineTimer{cancel: make(chan struct{})}
+}
+
+// done returns the current deadline's channel, which is closed once that
+// deadline fires. A reset replaces it with a new channel and leaves the
+// previous one open.
+func (d *deadlineTimer) done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+// reset arms the timer to close done() after dur, relative to now. A zero or
+// negative dur disables the deadline.
+func (d *deadlineTimer) reset(dur time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.cancel = make(chan struct{})
+
+	if dur <= 0 {
+		return
+	}
+
+	cancel := d.cancel
+	d.timer = time.AfterFunc(dur, func() {
+		close(cancel)
+	})
+}
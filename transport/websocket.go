@@ -0,0 +1,199 @@
+// Package transport provides delivery mechanisms for fir's dom.Event stream,
+// decoupled from rendering. Websocket is the default, built on gorilla/websocket.
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Websocket is fir's default transport, delivering dom.Event payloads over a
+// gorilla/websocket connection per session.
+type Websocket struct {
+	upgrader websocket.Upgrader
+
+	readDeadline  time.Duration
+	writeDeadline time.Duration
+	pingInterval  time.Duration
+	pongWait      time.Duration
+
+	mu    sync.RWMutex
+	conns map[string]*wsConn
+}
+
+type wsConn struct {
+	conn   *websocket.Conn
+	cancel context.CancelFunc
+
+	// writeMu serializes conn.SetWriteDeadline/WriteMessage calls: gorilla/websocket
+	// permits only one concurrent writer, and Send and the background ping loop
+	// both write to the same connection.
+	writeMu sync.Mutex
+}
+
+func (c *wsConn) writeMessage(messageType int, payload []byte, writeDeadline time.Duration) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if writeDeadline > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+	}
+	return c.conn.WriteMessage(messageType, payload)
+}
+
+// WebsocketOption configures a Websocket transport.
+type WebsocketOption func(*Websocket)
+
+// WithReadDeadline sets the read deadline reapplied before every read. A zero
+// duration disables the deadline.
+func WithReadDeadline(d time.Duration) WebsocketOption {
+	return func(t *Websocket) { t.readDeadline = d }
+}
+
+// WithWriteDeadline sets the write deadline reapplied before every write. A zero
+// duration disables the deadline.
+func WithWriteDeadline(d time.Duration) WebsocketOption {
+	return func(t *Websocket) { t.writeDeadline = d }
+}
+
+// WithPingInterval sets how often a background ping is written to each
+// connection. A zero duration disables pinging.
+func WithPingInterval(d time.Duration) WebsocketOption {
+	return func(t *Websocket) { t.pingInterval = d }
+}
+
+// WithPongWait sets how long the transport waits for a pong before closing an
+// unresponsive connection. Only effective when WithPingInterval is also set.
+func WithPongWait(d time.Duration) WebsocketOption {
+	return func(t *Websocket) { t.pongWait = d }
+}
+
+// NewWebsocket creates a Websocket transport that accepts incoming connections
+// using upgrader.
+func NewWebsocket(upgrader websocket.Upgrader, opts ...WebsocketOption) *Websocket {
+	t := &Websocket{
+		upgrader: upgrader,
+		conns:    make(map[string]*wsConn),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Upgrade upgrades r into a websocket connection registered under sessionID so
+// Subscribe, Send and Close can address it afterwards. If a ping interval is
+// configured, a background goroutine pings the connection and closes it if the
+// client stops responding with pongs within the configured pong wait.
+func (t *Websocket) Upgrade(w http.ResponseWriter, r *http.Request, sessionID string) error {
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &wsConn{conn: conn, cancel: cancel}
+	t.mu.Lock()
+	t.conns[sessionID] = c
+	t.mu.Unlock()
+
+	if t.pingInterval > 0 {
+		go t.pingLoop(ctx, sessionID, c)
+	}
+	return nil
+}
+
+// pingLoop writes a ping every pingInterval and closes sessionID if no pong
+// arrives before pongWait elapses. Closing the connection ends Subscribe's read
+// loop, whose channel closing is what a caller's pubsub pump watches. ctx only
+// stops this loop once Close has been called for sessionID.
+func (t *Websocket) pingLoop(ctx context.Context, sessionID string, c *wsConn) {
+	ticker := time.NewTicker(t.pingInterval)
+	defer ticker.Stop()
+
+	pong := newDeadlineTimer()
+	if t.pongWait > 0 {
+		pong.reset(t.pongWait)
+		c.conn.SetPongHandler(func(string) error {
+			pong.reset(t.pongWait)
+			return nil
+		})
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pong.done():
+			t.Close(sessionID)
+			return
+		case <-ticker.C:
+			if err := c.writeMessage(websocket.PingMessage, nil, t.writeDeadline); err != nil {
+				t.Close(sessionID)
+				return
+			}
+		}
+	}
+}
+
+// Subscribe reads incoming client messages for sessionID until a read fails,
+// e.g. because the connection is closed. ctx is checked only while handing a
+// message to the returned channel, so a read blocked on an idle connection
+// returns once the connection is closed, not when ctx is canceled.
+func (t *Websocket) Subscribe(ctx context.Context, sessionID string) (<-chan []byte, error) {
+	t.mu.RLock()
+	c, ok := t.conns[sessionID]
+	t.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("transport: no websocket connection for session %s", sessionID)
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		for {
+			if t.readDeadline > 0 {
+				c.conn.SetReadDeadline(time.Now().Add(t.readDeadline))
+			}
+			_, payload, err := c.conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case out <- payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Send writes payload to the client identified by sessionID.
+func (t *Websocket) Send(ctx context.Context, sessionID string, payload []byte) error {
+	t.mu.RLock()
+	c, ok := t.conns[sessionID]
+	t.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("transport: no websocket connection for session %s", sessionID)
+	}
+	return c.writeMessage(websocket.TextMessage, payload, t.writeDeadline)
+}
+
+// Close terminates the websocket connection for sessionID, if any, and cancels
+// any background ping loop and Subscribe read loop started for it.
+func (t *Websocket) Close(sessionID string) error {
+	t.mu.Lock()
+	c, ok := t.conns[sessionID]
+	delete(t.conns, sessionID)
+	t.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	c.cancel()
+	return c.conn.Close()
+}
@@ -0,0 +1,107 @@
+package fir
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/livefir/fir/pubsub"
+)
+
+// wsUpgrader is implemented by Transports that need to upgrade an incoming
+// HTTP request into a persistent connection, registered under a sessionID,
+// before Send can address it. transport.Websocket is one; a long-polling or
+// in-memory test transport has no such handshake and simply won't implement it.
+type wsUpgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request, sessionID string) error
+}
+
+// WS returns an http.HandlerFunc serving the websocket endpoint for route,
+// mounted alongside Controller.Route's own handler. It upgrades the connection
+// through the configured Transport, then pumps the route's pubsub channel to
+// it: each pubsub.Event is coalesced by an eventFlusher and the resulting batch
+// is rendered with renderDOMEventsBatch and handed to Transport.Send. It is a
+// no-op 404 if websockets are disabled or route is unknown.
+func (c *controller) WS(routeID string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if c.disableWebsocket {
+			http.NotFound(w, r)
+			return
+		}
+		rt, ok := c.routes[routeID]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		u, ok := c.transport.(wsUpgrader)
+		if !ok {
+			http.Error(w, "transport does not support websocket upgrade", http.StatusNotImplemented)
+			return
+		}
+
+		sessionID := r.URL.Query().Get("session_id")
+		if sessionID == "" {
+			sessionID = shortuuid.New()
+		}
+		if err := u.Upgrade(w, r, sessionID); err != nil {
+			glog.Errorf("ws: upgrade error: %v \n", err)
+			return
+		}
+
+		ctx := r.Context()
+		// incoming is closed once the transport connection drops or is closed via
+		// CloseSession, which ends the pump below and so the pubsub subscription.
+		incoming, err := c.transport.Subscribe(ctx, sessionID)
+		if err != nil {
+			glog.Errorf("ws: transport subscribe error: %v \n", err)
+			c.transport.Close(sessionID)
+			return
+		}
+
+		channel := routeID
+		if c.channelFunc != nil {
+			if ch := c.channelFunc(r, routeID); ch != nil {
+				channel = *ch
+			}
+		}
+
+		events, err := c.pubsub.Subscribe(ctx, channel)
+		if err != nil {
+			glog.Errorf("ws: subscribe error: %v \n", err)
+			c.transport.Close(sessionID)
+			return
+		}
+
+		routeCtx := RouteContext{route: rt}
+		flusher := newEventFlusher(c.eventFlushInterval, func(batch []pubsub.Event) {
+			payload, err := json.Marshal(renderDOMEventsBatch(routeCtx, batch))
+			if err != nil {
+				glog.Errorf("ws: marshal error: %v \n", err)
+				return
+			}
+			if err := c.transport.Send(ctx, sessionID, payload); err != nil {
+				glog.Errorf("ws: send error: %v \n", err)
+			}
+		})
+
+		for {
+			select {
+			case <-ctx.Done():
+				c.transport.Close(sessionID)
+				return
+			case _, ok := <-incoming:
+				if !ok {
+					c.transport.Close(sessionID)
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					c.transport.Close(sessionID)
+					return
+				}
+				flusher.add(event)
+			}
+		}
+	}
+}